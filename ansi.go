@@ -0,0 +1,115 @@
+package easy_telnet
+
+import (
+	"fmt"
+	"regexp"
+	"sync/atomic"
+	"time"
+)
+
+// ESC is the escape character introducing ANSI/VT100 control sequences.
+const ESC = 0x1B
+
+// readCleanByte reads the next byte of actual output, transparently
+// discarding any ANSI/VT100 escape sequence it runs into, so that callers
+// never see the color codes and cursor moves modern switch CLIs litter
+// their output with.
+func (tc *Client) readCleanByte() (b byte, err error) {
+	for {
+		b, err = tc.ReadByte()
+		if err != nil || b != ESC {
+			return
+		}
+
+		if err = tc.consumeEscapeSequence(); err != nil {
+			return
+		}
+	}
+}
+
+// consumeEscapeSequence discards the bytes of a single escape sequence
+// whose leading ESC has already been consumed by the caller. It handles
+// CSI sequences (ESC [ ... final byte in 0x40-0x7E), OSC sequences
+// (ESC ] ... terminated by BEL or ESC \), and single-character sequences
+// such as ESC = or ESC >.
+func (tc *Client) consumeEscapeSequence() error {
+	b, err := tc.ReadByte()
+	if err != nil {
+		return err
+	}
+
+	switch b {
+	case '[':
+		for {
+			b, err = tc.ReadByte()
+			if err != nil {
+				return err
+			}
+			if b >= 0x40 && b <= 0x7E {
+				return nil
+			}
+		}
+	case ']':
+		for {
+			b, err = tc.ReadByte()
+			if err != nil {
+				return err
+			}
+			if b == 0x07 {
+				return nil
+			}
+			if b == ESC {
+				b, err = tc.ReadByte()
+				if err != nil {
+					return err
+				}
+				if b == '\\' {
+					return nil
+				}
+			}
+		}
+	default:
+		return nil
+	}
+}
+
+// ReadUntil reads cleaned (ANSI-stripped) output until it matches pattern,
+// enforcing a wall-clock deadline that is independent of the per-read
+// deadline set by WithTimeout, so a caller can give up on a device stuck
+// behind a pager or a partial banner instead of hanging forever on a
+// single slow read. Execute does not go through ReadUntil; it reads with
+// ReadUntilBanner and is bounded through ExecuteContext instead.
+func (tc *Client) ReadUntil(pattern string, timeout time.Duration) (output []byte, err error) {
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, err
+	}
+
+	var timedOut int32
+	timer := time.AfterFunc(timeout, func() {
+		atomic.StoreInt32(&timedOut, 1)
+		_ = tc.conn.SetReadDeadline(time.Now())
+	})
+	defer func() {
+		timer.Stop()
+		_ = tc.conn.SetReadDeadline(time.Now().Add(tc.timeout))
+	}()
+
+	output = make([]byte, 0, 64*1024)
+
+	for {
+		var b byte
+		b, err = tc.readCleanByte()
+		if err != nil {
+			if atomic.LoadInt32(&timedOut) == 1 {
+				err = fmt.Errorf("easy_telnet: ReadUntil: timed out after %s waiting for %q", timeout, pattern)
+			}
+			return
+		}
+
+		output = append(output, b)
+		if re.Match(output) {
+			return
+		}
+	}
+}
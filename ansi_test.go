@@ -0,0 +1,99 @@
+package easy_telnet
+
+import (
+	"bytes"
+	"io"
+	"net"
+	"testing"
+	"time"
+)
+
+func TestReadUntilStripsAnsiSequences(t *testing.T) {
+	tc := dialFakeServer(t, func(conn net.Conn) {
+		_, _ = conn.Write([]byte("prompt$ "))
+		_, _ = conn.Write([]byte("\x1b[31mhello\x1b[0m world\x1b]0;title\x07!\x1b=\r\n"))
+	})
+
+	if err := tc.Dial(); err != nil {
+		t.Fatal(err)
+	}
+	defer tc.Close()
+
+	output, err := tc.ReadUntil(`\r\n`, time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := "hello world!\r\n"
+	if string(output) != want {
+		t.Fatalf("got %q, want %q", output, want)
+	}
+}
+
+func TestExecuteStripsAnsiAroundPromptAndOutput(t *testing.T) {
+	tc := dialFakeServer(t, func(conn net.Conn) {
+		_, _ = conn.Write([]byte("\x1b[1mprompt$ \x1b[0m"))
+
+		buf := make([]byte, len("show version\r\n"))
+		if _, err := io.ReadFull(conn, buf); err != nil {
+			t.Errorf("reading command: %v", err)
+			return
+		}
+
+		_, _ = conn.Write([]byte("\x1b[32mhello\x1b[0m\r\n\x1b[1mprompt$ \x1b[0m"))
+	})
+
+	if err := tc.Dial(); err != nil {
+		t.Fatal(err)
+	}
+	defer tc.Close()
+
+	stdout, err := tc.Execute("show", "version")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := "hello\r\n"
+	if string(stdout) != want {
+		t.Fatalf("got %q, want %q", stdout, want)
+	}
+}
+
+func TestReadUntilTimesOut(t *testing.T) {
+	tc := dialFakeServer(t, func(conn net.Conn) {
+		_, _ = conn.Write([]byte("prompt$ "))
+		time.Sleep(200 * time.Millisecond)
+	})
+
+	if err := tc.Dial(); err != nil {
+		t.Fatal(err)
+	}
+	defer tc.Close()
+
+	_, err := tc.ReadUntil(`never`, 20*time.Millisecond)
+	if err == nil {
+		t.Fatal("expected a timeout error")
+	}
+}
+
+func TestReadUntilCapturesRawLog(t *testing.T) {
+	var raw bytes.Buffer
+
+	tc := dialFakeServer(t, func(conn net.Conn) {
+		_, _ = conn.Write([]byte("prompt$ "))
+		_, _ = conn.Write([]byte("\x1b[31mok\r\n"))
+	}, WithRawLog(&raw))
+
+	if err := tc.Dial(); err != nil {
+		t.Fatal(err)
+	}
+	defer tc.Close()
+
+	if _, err := tc.ReadUntil(`\r\n`, time.Second); err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.Contains(raw.Bytes(), []byte("\x1b[31m")) {
+		t.Fatalf("expected raw log to contain the escape sequence, got %q", raw.Bytes())
+	}
+}
@@ -0,0 +1,37 @@
+package easy_telnet
+
+import (
+	"context"
+	"time"
+)
+
+// watchContext arms a goroutine that unblocks any in-flight read on tc.conn
+// as soon as ctx is done, by pulling its read deadline to now. It returns a
+// stop function that must be called once the operation guarded by ctx is
+// over, which also restores the ordinary per-read deadline.
+func (tc *Client) watchContext(ctx context.Context) (stop func()) {
+	done := make(chan struct{})
+
+	go func() {
+		select {
+		case <-ctx.Done():
+			_ = tc.conn.SetReadDeadline(time.Now())
+		case <-done:
+		}
+	}()
+
+	return func() {
+		close(done)
+		_ = tc.conn.SetReadDeadline(time.Now().Add(tc.timeout))
+	}
+}
+
+// ctxErr reports ctx.Err() instead of err when ctx is the reason a read
+// unblocked, so callers can tell a cancellation apart from an ordinary
+// network timeout.
+func ctxErr(ctx context.Context, err error) error {
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	return err
+}
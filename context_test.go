@@ -0,0 +1,63 @@
+package easy_telnet
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+	"time"
+)
+
+func TestExecuteContextCancellation(t *testing.T) {
+	tc := dialFakeServer(t, func(conn net.Conn) {
+		_, _ = conn.Write([]byte("prompt$ "))
+		// Swallow the command but never answer it, forcing ExecuteContext
+		// to rely on the context deadline rather than the banner match.
+		buf := make([]byte, 64)
+		_, _ = conn.Read(buf)
+		time.Sleep(time.Second)
+	})
+
+	if err := tc.Dial(); err != nil {
+		t.Fatal(err)
+	}
+	defer tc.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Millisecond)
+	defer cancel()
+
+	_, err := tc.ExecuteContext(ctx, "show", "version")
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected context.DeadlineExceeded, got %v", err)
+	}
+}
+
+func TestDialContextCancellation(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		// Accept but never send the banner, forcing DialContext to rely
+		// on the context deadline.
+		time.Sleep(time.Second)
+	}()
+
+	addr := ln.Addr().(*net.TCPAddr)
+	tc := NewClient("127.0.0.1", WithPort(addr.Port), WithTimeout(2*time.Second))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Millisecond)
+	defer cancel()
+
+	err = tc.DialContext(ctx)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected context.DeadlineExceeded, got %v", err)
+	}
+}
@@ -0,0 +1,352 @@
+package easy_telnet
+
+// Telnet option codes this package knows how to negotiate.
+const (
+	optEcho       = 1
+	optSGA        = 3
+	optTTYPE      = 24
+	optNAWS       = 31
+	optNEWENVIRON = 39
+)
+
+// TTYPE/NEW-ENVIRON sub-negotiation command bytes (RFC 1091, RFC 1572).
+const (
+	tnIS      = 0
+	tnSEND    = 1
+	tnVar     = 0
+	tnValue   = 1
+	tnEsc     = 2
+	tnUserVar = 3
+)
+
+// telnetState is a per-side option state, as defined by RFC 1143's "Q
+// method". It exists to avoid the infinite negotiation loops that a naive
+// "always answer WILL/DO with WILL/DO" implementation can trigger.
+type telnetState int
+
+const (
+	stateNo telnetState = iota
+	stateYes
+	stateWantNoEmpty
+	stateWantNoOpposite
+	stateWantYesEmpty
+	stateWantYesOpposite
+)
+
+// optionState tracks negotiation state for one telnet option, independently
+// for each direction: us is whether we perform the option, him is whether
+// the remote peer performs it.
+type optionState struct {
+	us, him telnetState
+}
+
+// negotiator answers WILL/WONT/DO/DONT requests from the remote side on
+// behalf of a Client, following RFC 1143, and handles the sub-negotiations
+// (TTYPE, NAWS, NEW-ENVIRON) of the options it accepts.
+type negotiator struct {
+	tc *Client
+
+	options map[byte]*optionState
+	accept  map[byte]bool
+
+	termType string
+	cols     uint16
+	rows     uint16
+	env      map[string]string
+}
+
+func newNegotiator(tc *Client) *negotiator {
+	return &negotiator{
+		tc:      tc,
+		options: make(map[byte]*optionState),
+		accept: map[byte]bool{
+			optEcho:       true,
+			optSGA:        true,
+			optTTYPE:      true,
+			optNAWS:       true,
+			optNEWENVIRON: true,
+		},
+		termType: tc.termType,
+		cols:     tc.nawsCols,
+		rows:     tc.nawsRows,
+		env:      tc.env,
+	}
+}
+
+func (n *negotiator) stateFor(option byte) *optionState {
+	st, ok := n.options[option]
+	if !ok {
+		st = &optionState{}
+		n.options[option] = st
+	}
+	return st
+}
+
+func (n *negotiator) send(cmd, option byte) error {
+	_, err := n.tc.Write([]byte{IAC, cmd, option})
+	return err
+}
+
+// handleCommand reads the command byte (and, for WILL/WONT/DO/DONT, the
+// option byte) that follows an IAC already consumed by Client.ReadByte, and
+// reacts to it. It is the replacement for the old skipCommand.
+func (n *negotiator) handleCommand() error {
+	cmd, err := n.tc.reader.ReadByte()
+	if err != nil {
+		return err
+	}
+
+	switch cmd {
+	case WILL, WONT, DO, DONT:
+		var option byte
+		option, err = n.tc.reader.ReadByte()
+		if err != nil {
+			return err
+		}
+		return n.handle(cmd, option)
+	case SB:
+		return n.handleSubnegotiation()
+	}
+
+	return nil
+}
+
+func (n *negotiator) handle(cmd, option byte) error {
+	st := n.stateFor(option)
+
+	switch cmd {
+	case DO:
+		return n.recvDo(option, st)
+	case DONT:
+		return n.recvDont(option, st)
+	case WILL:
+		return n.recvWill(option, st)
+	case WONT:
+		return n.recvWont(option, st)
+	}
+
+	return nil
+}
+
+// recvDo and recvDont track st.us: whether we (the client) perform option.
+func (n *negotiator) recvDo(option byte, st *optionState) error {
+	switch st.us {
+	case stateNo:
+		if n.accept[option] {
+			st.us = stateYes
+			if err := n.send(WILL, option); err != nil {
+				return err
+			}
+			return n.onUsEnabled(option)
+		}
+		return n.send(WONT, option)
+	case stateWantNoOpposite:
+		st.us = stateWantNoEmpty
+	case stateWantYesEmpty:
+		st.us = stateYes
+		return n.onUsEnabled(option)
+	case stateWantYesOpposite:
+		st.us = stateWantNoEmpty
+		return n.send(WONT, option)
+	case stateYes, stateWantNoEmpty:
+		// Already in this state, or peer answered a request we didn't
+		// make: nothing to send.
+	}
+
+	return nil
+}
+
+func (n *negotiator) recvDont(option byte, st *optionState) error {
+	switch st.us {
+	case stateYes:
+		st.us = stateNo
+		return n.send(WONT, option)
+	case stateWantNoOpposite:
+		st.us = stateWantYesEmpty
+		return n.send(WILL, option)
+	case stateWantNoEmpty, stateWantYesEmpty, stateWantYesOpposite:
+		st.us = stateNo
+	case stateNo:
+		// Already disabled.
+	}
+
+	return nil
+}
+
+// recvWill and recvWont track st.him: whether the remote peer performs
+// option.
+func (n *negotiator) recvWill(option byte, st *optionState) error {
+	switch st.him {
+	case stateNo:
+		if n.accept[option] {
+			st.him = stateYes
+			if err := n.send(DO, option); err != nil {
+				return err
+			}
+		} else if err := n.send(DONT, option); err != nil {
+			return err
+		}
+	case stateWantNoOpposite:
+		st.him = stateWantNoEmpty
+	case stateWantYesEmpty:
+		st.him = stateYes
+	case stateWantYesOpposite:
+		st.him = stateWantNoEmpty
+		if err := n.send(DONT, option); err != nil {
+			return err
+		}
+	case stateYes, stateWantNoEmpty:
+		// Already in this state, or peer answered a request we didn't
+		// make: nothing to send.
+	}
+
+	n.afterHimChange(option, st.him)
+	return nil
+}
+
+func (n *negotiator) recvWont(option byte, st *optionState) error {
+	switch st.him {
+	case stateYes:
+		st.him = stateNo
+		if err := n.send(DONT, option); err != nil {
+			return err
+		}
+	case stateWantNoOpposite:
+		st.him = stateWantYesEmpty
+		if err := n.send(DO, option); err != nil {
+			return err
+		}
+	case stateWantNoEmpty, stateWantYesEmpty, stateWantYesOpposite:
+		st.him = stateNo
+	case stateNo:
+		// Already disabled.
+	}
+
+	n.afterHimChange(option, st.him)
+	return nil
+}
+
+// onUsEnabled fires the side effects of an option becoming active on our
+// side (st.us reaching YES).
+func (n *negotiator) onUsEnabled(option byte) error {
+	if option == optNAWS {
+		return n.sendWindowSize()
+	}
+	return nil
+}
+
+// afterHimChange fires the side effects of the remote side's state for
+// option changing.
+func (n *negotiator) afterHimChange(option byte, s telnetState) {
+	if option == optEcho {
+		n.tc.remoteEcho = s == stateYes
+	}
+}
+
+// handleSubnegotiation reads an SB ... IAC SE block (the IAC SB has already
+// been consumed) and dispatches it. It unescapes the doubled IAC that RFC
+// 855 requires for literal 0xFF bytes inside sub-negotiation data.
+func (n *negotiator) handleSubnegotiation() error {
+	option, err := n.tc.reader.ReadByte()
+	if err != nil {
+		return err
+	}
+
+	var payload []byte
+	for {
+		var b byte
+		b, err = n.tc.reader.ReadByte()
+		if err != nil {
+			return err
+		}
+
+		if b == IAC {
+			var peeked []byte
+			peeked, err = n.tc.reader.Peek(1)
+			if err != nil {
+				return err
+			}
+
+			if peeked[0] == SE {
+				if _, err = n.tc.reader.Discard(1); err != nil {
+					return err
+				}
+				return n.dispatchSubnegotiation(option, payload)
+			}
+
+			if peeked[0] == IAC {
+				if _, err = n.tc.reader.Discard(1); err != nil {
+					return err
+				}
+				payload = append(payload, IAC)
+				continue
+			}
+		}
+
+		payload = append(payload, b)
+	}
+}
+
+func (n *negotiator) dispatchSubnegotiation(option byte, payload []byte) error {
+	if len(payload) == 0 {
+		return nil
+	}
+
+	switch option {
+	case optTTYPE:
+		if payload[0] == tnSEND {
+			return n.sendTerminalType()
+		}
+	case optNEWENVIRON:
+		if payload[0] == tnSEND {
+			return n.sendNewEnviron()
+		}
+	}
+
+	return nil
+}
+
+func (n *negotiator) sendTerminalType() error {
+	resp := []byte{IAC, SB, optTTYPE, tnIS}
+	resp = append(resp, []byte(n.termType)...)
+	resp = append(resp, IAC, SE)
+
+	_, err := n.tc.Write(resp)
+	return err
+}
+
+func (n *negotiator) sendNewEnviron() error {
+	resp := []byte{IAC, SB, optNEWENVIRON, tnIS}
+	for k, v := range n.env {
+		resp = append(resp, tnVar)
+		resp = append(resp, []byte(k)...)
+		resp = append(resp, tnValue)
+		resp = append(resp, []byte(v)...)
+	}
+	resp = append(resp, IAC, SE)
+
+	_, err := n.tc.Write(resp)
+	return err
+}
+
+func (n *negotiator) sendWindowSize() error {
+	resp := []byte{IAC, SB, optNAWS}
+	resp = appendSBByte(resp, byte(n.cols>>8))
+	resp = appendSBByte(resp, byte(n.cols))
+	resp = appendSBByte(resp, byte(n.rows>>8))
+	resp = appendSBByte(resp, byte(n.rows))
+	resp = append(resp, IAC, SE)
+
+	_, err := n.tc.Write(resp)
+	return err
+}
+
+// appendSBByte appends b to buf, doubling it if it happens to be IAC, as
+// required inside sub-negotiation data by RFC 855.
+func appendSBByte(buf []byte, b byte) []byte {
+	buf = append(buf, b)
+	if b == IAC {
+		buf = append(buf, IAC)
+	}
+	return buf
+}
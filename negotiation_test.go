@@ -0,0 +1,118 @@
+package easy_telnet
+
+import (
+	"io"
+	"net"
+	"testing"
+	"time"
+)
+
+// dialFakeServer starts a listener on 127.0.0.1 and hands the accepted
+// connection to handle in its own goroutine, returning a Client already
+// pointed at it.
+func dialFakeServer(t *testing.T, handle func(conn net.Conn), opts ...Option) *Client {
+	t.Helper()
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = ln.Close() })
+
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		handle(conn)
+	}()
+
+	addr := ln.Addr().(*net.TCPAddr)
+	allOpts := append([]Option{
+		WithPort(addr.Port),
+		WithTimeout(2 * time.Second),
+		WithPromptBanner("prompt\\$"),
+	}, opts...)
+
+	return NewClient("127.0.0.1", allOpts...)
+}
+
+func TestNegotiateAcceptsKnownRefusesUnknown(t *testing.T) {
+	replyCh := make(chan []byte, 1)
+
+	tc := dialFakeServer(t, func(conn net.Conn) {
+		_, _ = conn.Write([]byte{IAC, WILL, optEcho, IAC, DO, 99})
+
+		reply := make([]byte, 6)
+		_, _ = io.ReadFull(conn, reply)
+		replyCh <- reply
+
+		_, _ = conn.Write([]byte("prompt$ "))
+	})
+
+	if err := tc.Dial(); err != nil {
+		t.Fatal(err)
+	}
+	defer tc.Close()
+
+	reply := <-replyCh
+	want := []byte{IAC, DO, optEcho, IAC, WONT, 99}
+	if string(reply) != string(want) {
+		t.Fatalf("unexpected negotiation reply: % x, want % x", reply, want)
+	}
+
+	if !tc.remoteEcho {
+		t.Fatal("expected remoteEcho to be true once WILL ECHO was accepted")
+	}
+}
+
+func TestNegotiateTTYPESubnegotiation(t *testing.T) {
+	replyCh := make(chan []byte, 1)
+
+	tc := dialFakeServer(t, func(conn net.Conn) {
+		_, _ = conn.Write([]byte{IAC, SB, optTTYPE, tnSEND, IAC, SE})
+
+		reply := make([]byte, 11)
+		_, _ = io.ReadFull(conn, reply)
+		replyCh <- reply
+
+		_, _ = conn.Write([]byte("prompt$ "))
+	}, WithTerminalType("vt100"))
+
+	if err := tc.Dial(); err != nil {
+		t.Fatal(err)
+	}
+	defer tc.Close()
+
+	reply := <-replyCh
+	want := append([]byte{IAC, SB, optTTYPE, tnIS}, append([]byte("vt100"), IAC, SE)...)
+	if string(reply) != string(want) {
+		t.Fatalf("unexpected TTYPE reply: % x, want % x", reply, want)
+	}
+}
+
+func TestNegotiateNAWSSentAfterAccept(t *testing.T) {
+	replyCh := make(chan []byte, 1)
+
+	tc := dialFakeServer(t, func(conn net.Conn) {
+		_, _ = conn.Write([]byte{IAC, DO, optNAWS})
+
+		reply := make([]byte, 12)
+		_, _ = io.ReadFull(conn, reply)
+		replyCh <- reply
+
+		_, _ = conn.Write([]byte("prompt$ "))
+	}, WithWindowSize(132, 43))
+
+	if err := tc.Dial(); err != nil {
+		t.Fatal(err)
+	}
+	defer tc.Close()
+
+	reply := <-replyCh
+	want := []byte{IAC, WILL, optNAWS, IAC, SB, optNAWS, 0, 132, 0, 43, IAC, SE}
+	if string(reply) != string(want) {
+		t.Fatalf("unexpected NAWS reply: % x, want % x", reply, want)
+	}
+}
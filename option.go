@@ -1,6 +1,8 @@
 package easy_telnet
 
 import (
+	"crypto/tls"
+	"io"
 	"regexp"
 	"time"
 )
@@ -57,3 +59,78 @@ func WithPromptBanner(prompt string) Option {
 		g.promptBanner = reProm
 	}
 }
+
+// WithTerminalType sets the value reported in response to a TTYPE SEND
+// sub-negotiation. Defaults to "xterm".
+func WithTerminalType(termType string) Option {
+	return func(g *Client) {
+		g.termType = termType
+	}
+}
+
+// WithWindowSize sets the terminal size reported via NAWS once it is
+// negotiated. Defaults to 80x24.
+func WithWindowSize(cols, rows uint16) Option {
+	return func(g *Client) {
+		g.nawsCols = cols
+		g.nawsRows = rows
+	}
+}
+
+// WithEnv registers variables reported in response to a NEW-ENVIRON SEND
+// sub-negotiation. Unset by default, which answers with an empty list.
+func WithEnv(env map[string]string) Option {
+	return func(g *Client) {
+		g.env = env
+	}
+}
+
+// WithRawLog makes the client copy every byte it reads off the wire
+// (after telnet command handling, before ANSI stripping) to w, for
+// debugging sessions where the cleaned-up output hides what actually
+// came from the device.
+func WithRawLog(w io.Writer) Option {
+	return func(g *Client) {
+		g.rawLog = w
+	}
+}
+
+// WithPager configures the prompts (regexps matched against a line of
+// output) that mean the remote device is paginating, and the bytes to
+// write in response to make it print the next page. Defaults cover common
+// Cisco/HP/D-Link/Huawei "--More--" style prompts, responding with " ".
+func WithPager(patterns []string, response []byte) Option {
+	res := compilePagerPatterns(patterns)
+	return func(g *Client) {
+		g.pagerPatterns = res
+		g.pagerResponse = response
+	}
+}
+
+// WithDisablePaging registers commands (e.g. "terminal length 0") sent
+// right after login, so output never needs to go through the WithPager
+// path at all.
+func WithDisablePaging(commands []string) Option {
+	return func(g *Client) {
+		g.disablePagingCmds = commands
+	}
+}
+
+// WithTransport overrides how Dial opens the underlying connection.
+// Defaults to a TCPTransport built from Address/Port; set this to a
+// TLSTransport, SSHTransport or SerialTransport to drive the same
+// prompt/banner/pager machinery over a different kind of link.
+func WithTransport(transport Transport) Option {
+	return func(g *Client) {
+		g.transport = transport
+	}
+}
+
+// WithTLSConfig is shorthand for WithTransport(&TLSTransport{...}) using
+// the client's own Address/Port, for telnets/securetelnet (port 992)
+// style TLS-wrapped telnet.
+func WithTLSConfig(config *tls.Config) Option {
+	return func(g *Client) {
+		g.tlsConfig = config
+	}
+}
@@ -0,0 +1,88 @@
+package easy_telnet
+
+import "regexp"
+
+var (
+	defaultPagerPatterns = []string{
+		`-+\s*[Mm]ore\s*-+`,
+		`[Pp]ress any key to continue`,
+	}
+	defaultPagerResponse = []byte(" ")
+)
+
+// matchPager reports whether chunk looks like a pagination prompt such as
+// Cisco's "--More--" or Huawei's "---- More ----".
+func (tc *Client) matchPager(chunk []byte) bool {
+	for _, re := range tc.pagerPatterns {
+		if re.Match(chunk) {
+			return true
+		}
+	}
+	return false
+}
+
+// skipPagerErase discards the backspace/space/backspace runs (`\b \b`) many
+// devices emit right after a key is pressed, to erase the pager prompt from
+// the screen, so they don't leak into the accumulated output.
+func (tc *Client) skipPagerErase() error {
+	for {
+		peeked, err := tc.reader.Peek(1)
+		if err != nil {
+			return err
+		}
+		if peeked[0] != '\b' {
+			return nil
+		}
+
+		if _, err = tc.reader.Discard(1); err != nil {
+			return err
+		}
+
+		peeked, err = tc.reader.Peek(1)
+		if err != nil {
+			return err
+		}
+		if peeked[0] != ' ' {
+			continue
+		}
+		if _, err = tc.reader.Discard(1); err != nil {
+			return err
+		}
+
+		peeked, err = tc.reader.Peek(1)
+		if err != nil {
+			return err
+		}
+		if peeked[0] == '\b' {
+			if _, err = tc.reader.Discard(1); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// sendDisablePagingCommands runs the commands registered through
+// WithDisablePaging right after login, waiting for each one's prompt before
+// sending the next, so later calls to Execute don't trip over their output.
+func (tc *Client) sendDisablePagingCommands() (err error) {
+	for _, cmd := range tc.disablePagingCmds {
+		tc.log("Disabling paging: %s", cmd)
+		if _, err = tc.Write([]byte(cmd + "\r\n")); err != nil {
+			return
+		}
+		if _, err = tc.ReadUntilBanner(); err != nil {
+			return
+		}
+	}
+
+	return
+}
+
+// compilePagerPatterns is a helper for WithPager.
+func compilePagerPatterns(patterns []string) []*regexp.Regexp {
+	res := make([]*regexp.Regexp, len(patterns))
+	for i, p := range patterns {
+		res[i] = regexp.MustCompile(p)
+	}
+	return res
+}
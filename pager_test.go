@@ -0,0 +1,66 @@
+package easy_telnet
+
+import (
+	"io"
+	"net"
+	"strings"
+	"testing"
+)
+
+func TestReadUntilBannerSkipsPagerPrompts(t *testing.T) {
+	tc := dialFakeServer(t, func(conn net.Conn) {
+		_, _ = conn.Write([]byte("prompt$ "))
+
+		// First "page", then a pager prompt with its backspace erase
+		// sequence, then the rest of the output and the real prompt. Real
+		// devices block here for a keystroke and send no trailing space,
+		// so the prompt must be detected without a delimiter.
+		_, _ = conn.Write([]byte("line one\r\n--More--"))
+
+		keystroke := make([]byte, 1)
+		_, _ = io.ReadFull(conn, keystroke)
+		if keystroke[0] != ' ' {
+			t.Errorf("expected pager response ' ', got %q", keystroke)
+		}
+
+		_, _ = conn.Write([]byte(strings.Repeat("\b \b", len("--More--"))))
+		_, _ = conn.Write([]byte("line two\r\nprompt$ "))
+	})
+
+	if err := tc.Dial(); err != nil {
+		t.Fatal(err)
+	}
+	defer tc.Close()
+
+	stdout, err := tc.ReadUntilBanner()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := "line one\r\nline two\r\n"
+	if string(stdout) != want {
+		t.Fatalf("got %q, want %q", stdout, want)
+	}
+}
+
+func TestDisablePagingSendsCommandsAfterLogin(t *testing.T) {
+	var seen []string
+
+	tc := dialFakeServer(t, func(conn net.Conn) {
+		_, _ = conn.Write([]byte("prompt$ "))
+
+		buf := make([]byte, 64)
+		n, _ := conn.Read(buf)
+		seen = append(seen, string(buf[:n]))
+		_, _ = conn.Write([]byte("prompt$ "))
+	}, WithDisablePaging([]string{"terminal length 0"}))
+
+	if err := tc.Dial(); err != nil {
+		t.Fatal(err)
+	}
+	defer tc.Close()
+
+	if len(seen) != 1 || seen[0] != "terminal length 0\r\n" {
+		t.Fatalf("expected disable-paging command to be sent, got %q", seen)
+	}
+}
@@ -0,0 +1,191 @@
+package easy_telnet
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+var (
+	defaultPoolWorkers = 10
+	defaultPoolBackoff = time.Second
+)
+
+// PoolOption configures a Pool, the same way Option configures a Client.
+type PoolOption func(p *Pool)
+
+// WithWorkers caps how many hosts Pool dials concurrently. Defaults to 10.
+func WithWorkers(workers int) PoolOption {
+	return func(p *Pool) {
+		p.Workers = workers
+	}
+}
+
+// WithRetries sets how many additional attempts Pool makes per host after
+// a failed dial or script run. Defaults to 0 (no retry).
+func WithRetries(retries int) PoolOption {
+	return func(p *Pool) {
+		p.Retries = retries
+	}
+}
+
+// WithRetryBackoff sets the delay Pool waits before each retry. Defaults
+// to one second.
+func WithRetryBackoff(backoff time.Duration) PoolOption {
+	return func(p *Pool) {
+		p.Backoff = backoff
+	}
+}
+
+// HostConfig describes one target for Pool: the address to dial and the
+// Client options (credentials, prompts, ...) to use for it.
+type HostConfig struct {
+	Address string
+	Opts    []Option
+}
+
+// Result is what Pool reports for a single host once its commands have
+// run there, or once it has given up on it.
+type Result struct {
+	Host string
+
+	// Stdout holds one entry per command in Pool.Commands, as returned by
+	// Client.ExecuteScript.
+	Stdout [][]byte
+	Err    error
+
+	Duration time.Duration
+}
+
+// Pool fans a batch of commands out to many hosts concurrently, reusing a
+// single session per host via Client.ExecuteScript, so that
+// network-wide config audits don't pay for a reconnect per command.
+type Pool struct {
+	Hosts    []HostConfig
+	Commands []string
+
+	Workers int
+	Retries int
+	Backoff time.Duration
+}
+
+// NewPool builds a Pool for commands against hosts, applying opts over the
+// defaults (10 workers, no retries, one second backoff).
+func NewPool(hosts []HostConfig, commands []string, opts ...PoolOption) *Pool {
+	p := &Pool{Hosts: hosts, Commands: commands}
+
+	dftOpts := []PoolOption{
+		WithWorkers(defaultPoolWorkers),
+		WithRetryBackoff(defaultPoolBackoff),
+	}
+	dftOpts = append(dftOpts, opts...)
+
+	for _, fn := range dftOpts {
+		fn(p)
+	}
+
+	return p
+}
+
+// Run dials every host concurrently, waits for all of them and returns
+// their results keyed by "address:port" (see hostKey).
+func (p *Pool) Run(ctx context.Context) map[string]*Result {
+	results := make(map[string]*Result, len(p.Hosts))
+	for res := range p.Stream(ctx) {
+		results[res.Host] = res
+	}
+	return results
+}
+
+// Stream is the streaming variant of Run: it returns a channel that emits
+// each host's Result as soon as it completes, rather than waiting for the
+// whole batch. The channel is closed once every host has been handled.
+func (p *Pool) Stream(ctx context.Context) <-chan *Result {
+	out := make(chan *Result)
+
+	workers := p.Workers
+	if workers <= 0 {
+		workers = defaultPoolWorkers
+	}
+
+	go func() {
+		defer close(out)
+
+		sem := make(chan struct{}, workers)
+		var wg sync.WaitGroup
+
+		for _, host := range p.Hosts {
+			host := host
+
+			sem <- struct{}{}
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				defer func() { <-sem }()
+				out <- p.runHost(ctx, host)
+			}()
+		}
+
+		wg.Wait()
+	}()
+
+	return out
+}
+
+// hostKey identifies host in the results map as address:port, since
+// HostConfig.Address alone doesn't disambiguate hosts that only differ by
+// the port set through Opts (e.g. several devices reachable through the
+// same jump host on different ports).
+func hostKey(host HostConfig) string {
+	probe := NewClient(host.Address, host.Opts...)
+	probe.setDefaultParams()
+	return fmt.Sprintf("%s:%d", probe.Address, probe.Port)
+}
+
+// runHost dials host, runs Commands over that single session, and retries
+// on failure according to Retries/Backoff.
+func (p *Pool) runHost(ctx context.Context, host HostConfig) *Result {
+	start := time.Now()
+	res := &Result{Host: hostKey(host)}
+
+	backoff := p.Backoff
+	if backoff <= 0 {
+		backoff = defaultPoolBackoff
+	}
+
+	for attempt := 0; attempt <= p.Retries; attempt++ {
+		if attempt > 0 {
+			select {
+			case <-time.After(backoff):
+			case <-ctx.Done():
+				res.Err = ctxErr(ctx, ctx.Err())
+				res.Duration = time.Since(start)
+				return res
+			}
+		}
+
+		stdout, err := p.runHostOnce(ctx, host)
+		if err == nil {
+			res.Stdout = stdout
+			res.Duration = time.Since(start)
+			return res
+		}
+
+		res.Err = err
+	}
+
+	res.Duration = time.Since(start)
+	return res
+}
+
+func (p *Pool) runHostOnce(ctx context.Context, host HostConfig) ([][]byte, error) {
+	client := NewClient(host.Address, host.Opts...)
+
+	if err := client.DialContext(ctx); err != nil {
+		return nil, err
+	}
+	defer client.Close()
+
+	return client.ExecuteScriptContext(ctx, p.Commands)
+}
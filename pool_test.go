@@ -0,0 +1,139 @@
+package easy_telnet
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func startFakeTelnetHost(t *testing.T, handle func(conn net.Conn)) string {
+	t.Helper()
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = ln.Close() })
+
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		handle(conn)
+	}()
+
+	return ln.Addr().(*net.TCPAddr).String()
+}
+
+func echoHost(t *testing.T) string {
+	return startFakeTelnetHost(t, func(conn net.Conn) {
+		_, _ = conn.Write([]byte("prompt$ "))
+		buf := make([]byte, 64)
+		_, _ = conn.Read(buf)
+		_, _ = conn.Write([]byte("ok\r\nprompt$ "))
+	})
+}
+
+func hostConfig(t *testing.T, addr string) HostConfig {
+	t.Helper()
+
+	hostname, portStr, err := net.SplitHostPort(addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var port int
+	if _, err := fmt.Sscan(portStr, &port); err != nil {
+		t.Fatal(err)
+	}
+
+	return HostConfig{Address: hostname, Opts: []Option{
+		WithPort(port),
+		WithTimeout(3 * time.Second),
+		WithPromptBanner("prompt\\$"),
+	}}
+}
+
+func TestPoolRunAggregatesPerHostResults(t *testing.T) {
+	hosts := []HostConfig{
+		hostConfig(t, echoHost(t)),
+		hostConfig(t, echoHost(t)),
+	}
+
+	p := NewPool(hosts, []string{"show version"}, WithWorkers(2))
+
+	results := p.Run(context.Background())
+
+	if len(results) != 2 {
+		t.Fatalf("expected 2 results, got %d", len(results))
+	}
+	for host, res := range results {
+		if res.Err != nil {
+			t.Fatalf("host %s: unexpected error: %v", host, res.Err)
+		}
+		if len(res.Stdout) != 1 || string(res.Stdout[0]) != "ok\r\n" {
+			t.Fatalf("host %s: unexpected stdout %q", host, res.Stdout)
+		}
+	}
+}
+
+// failingTelnetHost accepts every connection but closes it immediately
+// without sending a banner, so each attempt against it fails with EOF
+// while waiting for the banner. attempts counts how many connections it accepted, so
+// tests can assert Pool actually retried instead of just getting an
+// error back.
+func failingTelnetHost(t *testing.T) (addr string, attempts *int32) {
+	t.Helper()
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = ln.Close() })
+
+	attempts = new(int32)
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			atomic.AddInt32(attempts, 1)
+			_ = conn.Close()
+		}
+	}()
+
+	return ln.Addr().String(), attempts
+}
+
+func TestPoolRetriesOnFailure(t *testing.T) {
+	addr, attempts := failingTelnetHost(t)
+
+	hc := hostConfig(t, addr)
+	hc.Opts = append(hc.Opts, WithTimeout(50*time.Millisecond))
+
+	const retries = 2
+	p := NewPool([]HostConfig{hc}, []string{"show version"}, WithRetries(retries), WithRetryBackoff(10*time.Millisecond))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	results := p.Run(ctx)
+
+	var res *Result
+	for _, r := range results {
+		res = r
+	}
+	if res == nil || res.Err == nil {
+		t.Fatalf("expected a dial error, got %+v", res)
+	}
+
+	if got := atomic.LoadInt32(attempts); got != retries+1 {
+		t.Fatalf("expected %d attempts (1 + %d retries), got %d", retries+1, retries, got)
+	}
+}
@@ -0,0 +1,36 @@
+package easy_telnet
+
+import (
+	"context"
+	"strings"
+)
+
+// ExecuteScript runs commands in order over the already logged-in
+// connection and returns one output per command, reusing the session
+// instead of reconnecting for each one.
+func (tc *Client) ExecuteScript(commands []string) ([][]byte, error) {
+	return tc.ExecuteScriptContext(context.Background(), commands)
+}
+
+// ExecuteScriptContext is ExecuteScript with a context that bounds the
+// whole sequence of commands.
+func (tc *Client) ExecuteScriptContext(ctx context.Context, commands []string) ([][]byte, error) {
+	outputs := make([][]byte, 0, len(commands))
+
+	for _, command := range commands {
+		fields := strings.Fields(command)
+		if len(fields) == 0 {
+			outputs = append(outputs, nil)
+			continue
+		}
+
+		stdout, err := tc.ExecuteContext(ctx, fields[0], fields[1:]...)
+		if err != nil {
+			return outputs, err
+		}
+
+		outputs = append(outputs, stdout)
+	}
+
+	return outputs, nil
+}
@@ -0,0 +1,43 @@
+package easy_telnet
+
+import (
+	"net"
+	"testing"
+)
+
+func TestExecuteScriptRunsEachCommandInOrder(t *testing.T) {
+	tc := dialFakeServer(t, func(conn net.Conn) {
+		_, _ = conn.Write([]byte("prompt$ "))
+
+		for _, want := range []string{"show version\r\n", "show clock\r\n"} {
+			buf := make([]byte, len(want))
+			if _, err := conn.Read(buf); err != nil {
+				t.Errorf("reading command: %v", err)
+				return
+			}
+			if string(buf) != want {
+				t.Errorf("got command %q, want %q", buf, want)
+			}
+			_, _ = conn.Write([]byte("output\r\nprompt$ "))
+		}
+	})
+
+	if err := tc.Dial(); err != nil {
+		t.Fatal(err)
+	}
+	defer tc.Close()
+
+	outputs, err := tc.ExecuteScript([]string{"show version", "show clock"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(outputs) != 2 {
+		t.Fatalf("expected 2 outputs, got %d", len(outputs))
+	}
+	for i, out := range outputs {
+		if string(out) != "output\r\n" {
+			t.Fatalf("output %d: got %q", i, out)
+		}
+	}
+}
@@ -3,7 +3,10 @@ package easy_telnet
 import (
 	"bufio"
 	"bytes"
+	"context"
+	"crypto/tls"
 	"fmt"
+	"io"
 	"os"
 	"regexp"
 	"strings"
@@ -42,6 +45,10 @@ var (
 	defaultUsernameRe = "[\\w\\d-_]+ username:"
 	defaultPasswordRe = "Password:"
 	defaultBannerRe   = "[\\w\\d-_]+@[\\w\\d-_]+:[\\w\\d/-_~]+(\\$|#)"
+
+	defaultTermType = "xterm"
+	defaultNAWSCols = uint16(80)
+	defaultNAWSRows = uint16(24)
 )
 
 // Client is basic descriptor
@@ -55,14 +62,33 @@ type Client struct {
 	verbose  bool
 
 	logWriter *bufio.Writer
+	rawLog    io.Writer
 
 	promptUsername *regexp.Regexp
 	promptPassword *regexp.Regexp
 	promptBanner   *regexp.Regexp
 
-	reader *bufio.Reader
-	writer *bufio.Writer
-	conn   net.Conn
+	termType string
+	nawsCols uint16
+	nawsRows uint16
+	env      map[string]string
+
+	pagerPatterns     []*regexp.Regexp
+	pagerResponse     []byte
+	disablePagingCmds []string
+
+	// remoteEcho reports whether the remote side has taken over echoing
+	// (WILL ECHO accepted). findInputPrompt reports it in the log line it
+	// writes for a secret response; what gets logged does not depend on it.
+	remoteEcho bool
+
+	transport Transport
+	tlsConfig *tls.Config
+
+	reader     *bufio.Reader
+	writer     *bufio.Writer
+	conn       net.Conn
+	negotiator *negotiator
 }
 
 func NewClient(address string, opts ...Option) *Client {
@@ -75,6 +101,11 @@ func NewClient(address string, opts ...Option) *Client {
 		WithPromptUsername(defaultUsernameRe),
 		WithPromptPassword(defaultPasswordRe),
 		WithPromptBanner(defaultBannerRe),
+
+		WithTerminalType(defaultTermType),
+		WithWindowSize(defaultNAWSCols, defaultNAWSRows),
+
+		WithPager(defaultPagerPatterns, defaultPagerResponse),
 	}
 
 	dftOpts = append(dftOpts, opts...)
@@ -105,6 +136,27 @@ func (tc *Client) setDefaultParams() {
 	if tc.promptBanner == nil {
 		tc.promptBanner = regexp.MustCompile(defaultBannerRe)
 	}
+	if tc.termType == "" {
+		tc.termType = defaultTermType
+	}
+}
+
+// resolveTransport returns the Transport to dial with: whatever
+// WithTransport set, a TLSTransport if WithTLSConfig set one, or a
+// TCPTransport built from Address/Port otherwise. An SSHTransport set via
+// WithTransport has its PTY term type/window size filled in from
+// WithTerminalType/WithWindowSize wherever it didn't already set them.
+func (tc *Client) resolveTransport() Transport {
+	if tc.transport != nil {
+		if sshTransport, ok := tc.transport.(*SSHTransport); ok {
+			sshTransport.applyClientDefaults(tc.termType, tc.nawsCols, tc.nawsRows)
+		}
+		return tc.transport
+	}
+	if tc.tlsConfig != nil {
+		return &TLSTransport{Address: tc.Address, Port: tc.Port, TLSConfig: tc.tlsConfig}
+	}
+	return &TCPTransport{Address: tc.Address, Port: tc.Port}
 }
 
 func (tc *Client) log(format string, params ...interface{}) {
@@ -115,74 +167,51 @@ func (tc *Client) log(format string, params ...interface{}) {
 }
 
 // Dial does open connect to telnet server
-func (tc *Client) Dial() (err error) {
+func (tc *Client) Dial() error {
+	return tc.DialContext(context.Background())
+}
+
+// DialContext is Dial with a context that bounds the whole login sequence
+// (connecting, option negotiation, authentication and disable-paging
+// commands), for callers that need to abort it from the outside.
+func (tc *Client) DialContext(ctx context.Context) (err error) {
 	tc.setDefaultParams()
 
 	tc.log("Trying connect to %s:%d", tc.Address, tc.Port)
-	tc.conn, err = net.Dial("tcp", fmt.Sprintf("%s:%d", tc.Address, tc.Port))
+	tc.conn, err = tc.resolveTransport().Dial(ctx)
 	if err != nil {
-		return
+		return ctxErr(ctx, err)
 	}
 
 	tc.reader = bufio.NewReader(tc.conn)
 	tc.writer = bufio.NewWriter(tc.conn)
+	tc.negotiator = newNegotiator(tc)
 	err = tc.conn.SetReadDeadline(time.Now().Add(tc.timeout))
 	if err != nil {
 		return
 	}
 
-	tc.log("Waiting for the first banner")
-	err = tc.waitWelcomeSigns()
-
-	return
-}
-
-func (tc *Client) Close() {
-	tc.conn.Close()
-}
-
-func (tc *Client) skipSBSequence() (err error) {
-	var peeked []byte
-
-	for {
-		_, err = tc.reader.Discard(1)
-		if err != nil {
-			return
-		}
+	stop := tc.watchContext(ctx)
+	defer stop()
 
-		peeked, err = tc.reader.Peek(2)
-		if err != nil {
-			return
-		}
+	tc.log("Waiting for the first banner")
+	if err = tc.waitWelcomeSigns(); err != nil {
+		return ctxErr(ctx, err)
+	}
 
-		if peeked[0] == IAC && peeked[1] == SE {
-			_, err = tc.reader.Discard(2)
-			break
-		}
+	if err = tc.sendDisablePagingCommands(); err != nil {
+		err = ctxErr(ctx, err)
 	}
 
 	return
 }
 
-func (tc *Client) skipCommand() (err error) {
-	var peeked []byte
-
-	peeked, err = tc.reader.Peek(1)
-	if err != nil {
-		return
-	}
-
-	switch peeked[0] {
-	case WILL, WONT, DO, DONT:
-		_, err = tc.reader.Discard(2)
-	case SB:
-		err = tc.skipSBSequence()
-	}
-
-	return
+func (tc *Client) Close() {
+	tc.conn.Close()
 }
 
-// ReadByte receives byte from remote server, avoiding commands
+// ReadByte receives byte from remote server, answering option negotiation
+// and sub-negotiation commands instead of the data stream
 func (tc *Client) ReadByte() (b byte, err error) {
 	for {
 		b, err = tc.reader.ReadByte()
@@ -190,28 +219,14 @@ func (tc *Client) ReadByte() (b byte, err error) {
 			break
 		}
 
-		err = tc.skipCommand()
+		err = tc.negotiator.handleCommand()
 		if err != nil {
 			break
 		}
 	}
 
-	return
-}
-
-// ReadUntil reads bytes until a specific symbol.
-// Delimiter character will be written to result buffer
-func (tc *Client) ReadUntil(data *[]byte, delim byte) (n int, err error) {
-	var b byte
-
-	for b != delim {
-		b, err = tc.ReadByte()
-		if err != nil {
-			break
-		}
-
-		*data = append(*data, b)
-		n++
+	if err == nil && tc.rawLog != nil {
+		_, _ = tc.rawLog.Write([]byte{b})
 	}
 
 	return
@@ -236,32 +251,55 @@ func findNewLinePos(data []byte) int {
 // If process function returns true, reading will be stopped
 // Process function give chunk of line i.e. from start of line
 // to last white space or whole line, if next line delimiter is found
+//
+// Pager prompts ("--More--", "Press any key to continue", ...) are
+// checked against the accumulated line once the reader has drained
+// everything the device has sent so far, since a device showing one
+// blocks for a keystroke instead of emitting a trailing delimiter for
+// readUntilByte to stop on. Checking only once buffered input runs dry
+// (rather than after every byte) also avoids reacting to a partial match
+// such as "--More-" with more of the prompt still unread.
 func (tc *Client) ReadUntilPrompt(process func(data []byte) bool) (output []byte, err error) {
-	var n int
-	var delimPos int
+	var b byte
 	var linePos int
 	var chunk []byte
 
 	output = make([]byte, 0, 64*1024)
 
 	for {
-		// Usually, if system print a prompt,
-		// it requires inputing data and
-		// prompt has ':' or whitespace in end of line.
-		// However, may be cases which have another behaviors.
-		// So client may freeze
-		n, err = tc.ReadUntil(&output, ' ')
+		b, err = tc.readCleanByte()
 		if err != nil {
 			return
 		}
 
-		delimPos += n
-		n = findNewLinePos(output)
-		if n != -1 {
+		output = append(output, b)
+		if n := findNewLinePos(output); n != -1 {
 			linePos = n + 2
 		}
 
-		chunk = output[linePos:delimPos]
+		chunk = output[linePos:]
+
+		if tc.reader.Buffered() == 0 && tc.matchPager(chunk) {
+			output = output[:linePos]
+
+			if _, err = tc.Write(tc.pagerResponse); err != nil {
+				return
+			}
+			if err = tc.skipPagerErase(); err != nil {
+				return
+			}
+
+			continue
+		}
+
+		// Usually, if system print a prompt,
+		// it requires inputing data and
+		// prompt has ':' or whitespace in end of line.
+		// However, may be cases which have another behaviors.
+		// So client may freeze
+		if b != ' ' {
+			continue
+		}
 
 		if process(chunk) {
 			break
@@ -284,7 +322,11 @@ func (tc *Client) ReadUntilBanner() (output []byte, err error) {
 	return
 }
 
-func (tc *Client) findInputPrompt(re *regexp.Regexp, response string, buffer []byte) bool {
+// findInputPrompt writes response once re matches buffer. secret marks
+// response as sensitive (e.g. a password): its value is never logged, and
+// the remote ECHO state is surfaced instead, since a server that takes
+// over echoing would otherwise cause it to show up a second time.
+func (tc *Client) findInputPrompt(re *regexp.Regexp, response string, buffer []byte, secret bool) bool {
 	match := re.Find(buffer)
 	if len(match) == 0 {
 		return false
@@ -295,6 +337,12 @@ func (tc *Client) findInputPrompt(re *regexp.Regexp, response string, buffer []b
 		return false
 	}
 
+	if secret {
+		tc.log("Sent response to prompt (hidden, remote echo=%v)", tc.remoteEcho)
+	} else {
+		tc.log("Sent response to prompt: %q", response)
+	}
+
 	return true
 }
 
@@ -302,11 +350,11 @@ func (tc *Client) findInputPrompt(re *regexp.Regexp, response string, buffer []b
 // If detect login prompt, it will authorize
 func (tc *Client) waitWelcomeSigns() (err error) {
 	_, err = tc.ReadUntilPrompt(func(data []byte) bool {
-		if tc.findInputPrompt(tc.promptUsername, tc.username, data) {
+		if tc.findInputPrompt(tc.promptUsername, tc.username, data, false) {
 			tc.log("Found Username prompt")
 			return false
 		}
-		if tc.password != "" && tc.findInputPrompt(tc.promptPassword, tc.password, data) {
+		if tc.password != "" && tc.findInputPrompt(tc.promptPassword, tc.password, data, true) {
 			tc.log("Found password prompt")
 			return false
 		}
@@ -329,21 +377,32 @@ func (tc *Client) Write(data []byte) (n int, err error) {
 }
 
 // Execute sends command on remote server and returns whole output
-func (tc *Client) Execute(name string, args ...string) (stdout []byte, err error) {
+func (tc *Client) Execute(name string, args ...string) ([]byte, error) {
+	return tc.ExecuteContext(context.Background(), name, args...)
+}
+
+// ExecuteContext is Execute with a context that bounds how long the command
+// is allowed to run, for callers that need to abort a stuck command (e.g.
+// one stuck behind an unrecognized pager prompt) instead of waiting for the
+// per-read timeout.
+func (tc *Client) ExecuteContext(ctx context.Context, name string, args ...string) (stdout []byte, err error) {
+	stop := tc.watchContext(ctx)
+	defer stop()
+
 	_, err = tc.reader.Discard(tc.reader.Buffered())
 	if err != nil {
-		return
+		return nil, ctxErr(ctx, err)
 	}
 
 	request := []byte(name + " " + strings.Join(args, " ") + "\r\n")
 	tc.log("Send command: %s", request[:len(request)-2])
 	if _, err = tc.Write(request); err != nil {
-		return nil, err
+		return nil, ctxErr(ctx, err)
 	}
 
 	stdout, err = tc.ReadUntilBanner()
 	if err != nil {
-		return
+		return nil, ctxErr(ctx, err)
 	}
 	tc.log("Received data with size = %d", len(stdout))
 
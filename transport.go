@@ -0,0 +1,234 @@
+package easy_telnet
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"io"
+	"net"
+	"sync"
+	"time"
+
+	"go.bug.st/serial"
+	"golang.org/x/crypto/ssh"
+)
+
+// Transport opens the underlying connection a Client reads and writes
+// through. Swapping it lets the same prompt/banner/pager/option
+// negotiation machinery drive a plain TCP telnet session, a TLS-wrapped
+// one, an SSH-only device, or a serial console, without Client itself
+// knowing the difference.
+type Transport interface {
+	Dial(ctx context.Context) (net.Conn, error)
+}
+
+// TCPTransport is the default Transport: a plain TCP connection, exactly
+// what Client dialed directly before Transport existed.
+type TCPTransport struct {
+	Address string
+	Port    int
+}
+
+func (t *TCPTransport) Dial(ctx context.Context) (net.Conn, error) {
+	var d net.Dialer
+	return d.DialContext(ctx, "tcp", fmt.Sprintf("%s:%d", t.Address, t.Port))
+}
+
+// TLSTransport wraps the TCP connection in TLS, for telnets/securetelnet
+// (port 992) and other modern equipment that puts telnet behind TLS
+// instead of speaking it in the clear.
+type TLSTransport struct {
+	Address   string
+	Port      int
+	TLSConfig *tls.Config
+}
+
+func (t *TLSTransport) Dial(ctx context.Context) (net.Conn, error) {
+	d := tls.Dialer{Config: t.TLSConfig}
+	return d.DialContext(ctx, "tcp", fmt.Sprintf("%s:%d", t.Address, t.Port))
+}
+
+// SSHTransport opens an SSH session and exposes its PTY's stdin/stdout
+// (with stderr merged in) as a net.Conn, so devices that only speak SSH
+// can be driven by the same prompt/banner/pager machinery as a telnet
+// session.
+//
+// TermType/Cols/Rows set the PTY requested over the session; any left
+// zero fall back to whatever the Client's WithTerminalType/WithWindowSize
+// set, applied by resolveTransport before Dial runs, and finally to
+// "xterm"/80x24 if neither was set.
+type SSHTransport struct {
+	Address string
+	Port    int
+	Config  *ssh.ClientConfig
+
+	TermType string
+	Cols     uint32
+	Rows     uint32
+}
+
+// applyClientDefaults fills in TermType/Cols/Rows from the Client's own
+// settings wherever this transport didn't already set them explicitly.
+func (t *SSHTransport) applyClientDefaults(termType string, cols, rows uint16) {
+	if t.TermType == "" {
+		t.TermType = termType
+	}
+	if t.Cols == 0 {
+		t.Cols = uint32(cols)
+	}
+	if t.Rows == 0 {
+		t.Rows = uint32(rows)
+	}
+}
+
+func (t *SSHTransport) Dial(ctx context.Context) (net.Conn, error) {
+	addr := fmt.Sprintf("%s:%d", t.Address, t.Port)
+
+	var d net.Dialer
+	tcpConn, err := d.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+
+	sshConn, chans, reqs, err := ssh.NewClientConn(tcpConn, addr, t.Config)
+	if err != nil {
+		_ = tcpConn.Close()
+		return nil, err
+	}
+	client := ssh.NewClient(sshConn, chans, reqs)
+
+	session, err := client.NewSession()
+	if err != nil {
+		_ = client.Close()
+		return nil, err
+	}
+
+	stdin, err := session.StdinPipe()
+	if err != nil {
+		_ = session.Close()
+		_ = client.Close()
+		return nil, err
+	}
+
+	// Merge stdout and stderr into a single stream, the same as a real
+	// terminal would show them, so device output sent to stderr isn't
+	// silently dropped.
+	output, combined := io.Pipe()
+	session.Stdout = combined
+	session.Stderr = combined
+
+	termType := t.TermType
+	if termType == "" {
+		termType = defaultTermType
+	}
+	cols := t.Cols
+	if cols == 0 {
+		cols = uint32(defaultNAWSCols)
+	}
+	rows := t.Rows
+	if rows == 0 {
+		rows = uint32(defaultNAWSRows)
+	}
+
+	if err = session.RequestPty(termType, int(rows), int(cols), ssh.TerminalModes{}); err != nil {
+		_ = session.Close()
+		_ = client.Close()
+		return nil, err
+	}
+	if err = session.Shell(); err != nil {
+		_ = session.Close()
+		_ = client.Close()
+		return nil, err
+	}
+
+	rwc := &sshSessionConn{Reader: output, Writer: stdin, session: session, client: client}
+	return newDeadlineConn(rwc, tcpConn.LocalAddr(), tcpConn.RemoteAddr()), nil
+}
+
+// sshSessionConn bundles an SSH session's stdio into a single
+// io.ReadWriteCloser, closing both the session and its client.
+type sshSessionConn struct {
+	io.Reader
+	io.Writer
+	session *ssh.Session
+	client  *ssh.Client
+}
+
+func (c *sshSessionConn) Close() error {
+	_ = c.session.Close()
+	return c.client.Close()
+}
+
+// SerialTransport opens a local serial port for console-cable access.
+type SerialTransport struct {
+	PortName string
+	Mode     *serial.Mode
+}
+
+func (t *SerialTransport) Dial(context.Context) (net.Conn, error) {
+	port, err := serial.Open(t.PortName, t.Mode)
+	if err != nil {
+		return nil, err
+	}
+
+	return newDeadlineConn(port, serialAddr(t.PortName), serialAddr(t.PortName)), nil
+}
+
+type serialAddr string
+
+func (a serialAddr) Network() string { return "serial" }
+func (a serialAddr) String() string  { return string(a) }
+
+// newDeadlineConn adapts an io.ReadWriteCloser that has no native concept
+// of deadlines (an SSH channel, a serial port) into a net.Conn.
+func newDeadlineConn(rwc io.ReadWriteCloser, local, remote net.Addr) net.Conn {
+	return &deadlineConn{ReadWriteCloser: rwc, localAddr: local, remoteAddr: remote}
+}
+
+// deadlineConn approximates net.Conn's per-operation deadlines by closing
+// the connection once the deadline elapses. That's coarser than a real
+// per-read deadline: once the deadline has fired the connection stays
+// closed, and a later SetReadDeadline does not reopen it. Client arms the
+// deadline in DialContext, re-arms it to its per-read timeout after each
+// ExecuteContext and ReadUntil, and pulls it to now on context
+// cancellation or a ReadUntil timeout; any of these that elapses ends the
+// session.
+type deadlineConn struct {
+	io.ReadWriteCloser
+	localAddr  net.Addr
+	remoteAddr net.Addr
+
+	mu    sync.Mutex
+	timer *time.Timer
+}
+
+func (c *deadlineConn) LocalAddr() net.Addr  { return c.localAddr }
+func (c *deadlineConn) RemoteAddr() net.Addr { return c.remoteAddr }
+
+func (c *deadlineConn) SetDeadline(t time.Time) error {
+	return c.SetReadDeadline(t)
+}
+
+func (c *deadlineConn) SetReadDeadline(t time.Time) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.timer != nil {
+		c.timer.Stop()
+		c.timer = nil
+	}
+
+	if t.IsZero() {
+		return nil
+	}
+
+	d := time.Until(t)
+	if d <= 0 {
+		return c.Close()
+	}
+
+	c.timer = time.AfterFunc(d, func() { _ = c.Close() })
+	return nil
+}
+
+func (c *deadlineConn) SetWriteDeadline(time.Time) error { return nil }
@@ -0,0 +1,270 @@
+package easy_telnet
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"io"
+	"math/big"
+	"net"
+	"testing"
+	"time"
+
+	"go.bug.st/serial"
+	"golang.org/x/crypto/ssh"
+)
+
+// generateTestCert builds a throwaway self-signed certificate so
+// TestTLSTransportDials doesn't depend on files on disk.
+func generateTestCert() (tls.Certificate, error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+
+	template := x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "127.0.0.1"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, &template, &template, &key.PublicKey, key)
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key}, nil
+}
+
+func TestTCPTransportDials(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+	}()
+
+	addr := ln.Addr().(*net.TCPAddr)
+	transport := &TCPTransport{Address: "127.0.0.1", Port: addr.Port}
+
+	conn, err := transport.Dial(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+}
+
+func TestTLSTransportDials(t *testing.T) {
+	cert, err := generateTestCert()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ln, err := tls.Listen("tcp", "127.0.0.1:0", &tls.Config{Certificates: []tls.Certificate{cert}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		_ = conn.(*tls.Conn).Handshake()
+	}()
+
+	addr := ln.Addr().(*net.TCPAddr)
+	transport := &TLSTransport{
+		Address:   "127.0.0.1",
+		Port:      addr.Port,
+		TLSConfig: &tls.Config{InsecureSkipVerify: true},
+	}
+
+	conn, err := transport.Dial(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+}
+
+func TestDialUsesWithTransportOverride(t *testing.T) {
+	tc := dialFakeServer(t, func(conn net.Conn) {
+		_, _ = conn.Write([]byte("prompt$ "))
+	})
+
+	used := false
+	WithTransport(transportFunc(func(ctx context.Context) (net.Conn, error) {
+		used = true
+		return (&TCPTransport{Address: tc.Address, Port: tc.Port}).Dial(ctx)
+	}))(tc)
+
+	if err := tc.Dial(); err != nil {
+		t.Fatal(err)
+	}
+	defer tc.Close()
+
+	if !used {
+		t.Fatal("expected Dial to use the Transport set via WithTransport")
+	}
+}
+
+type transportFunc func(ctx context.Context) (net.Conn, error)
+
+func (f transportFunc) Dial(ctx context.Context) (net.Conn, error) { return f(ctx) }
+
+// startFakeSSHServer starts a minimal in-process SSH server accepting one
+// connection, handing its "session" channel's requests (pty-req, shell)
+// to handle once they have been acknowledged.
+func startFakeSSHServer(t *testing.T, handle func(channel ssh.Channel, ptyTerm string, ptyRows, ptyCols uint32)) *net.TCPAddr {
+	t.Helper()
+
+	_, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signer, err := ssh.NewSignerFromSigner(priv)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	config := &ssh.ServerConfig{NoClientAuth: true}
+	config.AddHostKey(signer)
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = ln.Close() })
+
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+
+		sshConn, chans, reqs, err := ssh.NewServerConn(conn, config)
+		if err != nil {
+			return
+		}
+		defer sshConn.Close()
+		go ssh.DiscardRequests(reqs)
+
+		for newChannel := range chans {
+			if newChannel.ChannelType() != "session" {
+				_ = newChannel.Reject(ssh.UnknownChannelType, "only session channels supported")
+				continue
+			}
+
+			channel, requests, err := newChannel.Accept()
+			if err != nil {
+				return
+			}
+
+			var ptyTerm string
+			var ptyRows, ptyCols uint32
+
+			for req := range requests {
+				switch req.Type {
+				case "pty-req":
+					// string term, uint32 cols, uint32 rows, then pixel
+					// dims and modes, per RFC 4254 section 6.2.
+					payload := req.Payload
+					if len(payload) >= 4 {
+						nameLen := int(payload[0])<<24 | int(payload[1])<<16 | int(payload[2])<<8 | int(payload[3])
+						if len(payload) >= 4+nameLen+8 {
+							ptyTerm = string(payload[4 : 4+nameLen])
+							rest := payload[4+nameLen:]
+							ptyCols = uint32(rest[0])<<24 | uint32(rest[1])<<16 | uint32(rest[2])<<8 | uint32(rest[3])
+							ptyRows = uint32(rest[4])<<24 | uint32(rest[5])<<16 | uint32(rest[6])<<8 | uint32(rest[7])
+						}
+					}
+					if req.WantReply {
+						_ = req.Reply(true, nil)
+					}
+				case "shell":
+					if req.WantReply {
+						_ = req.Reply(true, nil)
+					}
+					go func() {
+						defer channel.Close()
+						handle(channel, ptyTerm, ptyRows, ptyCols)
+					}()
+				default:
+					if req.WantReply {
+						_ = req.Reply(false, nil)
+					}
+				}
+			}
+		}
+	}()
+
+	return ln.Addr().(*net.TCPAddr)
+}
+
+func TestSSHTransportMergesStreamsAndSetsPTYFromClient(t *testing.T) {
+	seen := make(chan [3]interface{}, 1)
+
+	addr := startFakeSSHServer(t, func(channel ssh.Channel, term string, rows, cols uint32) {
+		seen <- [3]interface{}{term, rows, cols}
+		_, _ = channel.Write([]byte("out\r\n"))
+		_, _ = channel.Stderr().Write([]byte("err\r\n"))
+	})
+
+	transport := &SSHTransport{
+		Address: "127.0.0.1",
+		Port:    addr.Port,
+		Config: &ssh.ClientConfig{
+			User:            "test",
+			Auth:            []ssh.AuthMethod{},
+			HostKeyCallback: ssh.InsecureIgnoreHostKey(),
+			Timeout:         2 * time.Second,
+		},
+	}
+	transport.applyClientDefaults("xterm", 132, 43)
+
+	conn, err := transport.Dial(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	got := <-seen
+	if got[0] != "xterm" || got[1] != uint32(43) || got[2] != uint32(132) {
+		t.Fatalf("expected PTY term=xterm rows=43 cols=132, got term=%v rows=%v cols=%v", got[0], got[1], got[2])
+	}
+
+	buf := make([]byte, len("out\r\nerr\r\n"))
+	if _, err := io.ReadFull(conn, buf); err != nil {
+		t.Fatal(err)
+	}
+	if string(buf) != "out\r\nerr\r\n" {
+		t.Fatalf("expected stdout and stderr merged, got %q", buf)
+	}
+}
+
+func TestSerialTransportDialReturnsErrorForMissingPort(t *testing.T) {
+	transport := &SerialTransport{
+		PortName: "/dev/this-port-does-not-exist",
+		Mode:     &serial.Mode{BaudRate: 9600},
+	}
+
+	if _, err := transport.Dial(context.Background()); err == nil {
+		t.Fatal("expected an error opening a nonexistent serial port")
+	}
+}